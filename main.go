@@ -1,24 +1,35 @@
 package main
 
 import (
-	"bufio"
+	"context"
+	"entryTask/pkg/apply"
+	"entryTask/pkg/metrics"
 	"entryTask/utils"
 	"flag"
 	"fmt"
-	"k8s.io/apimachinery/pkg/api/resource"
-	"k8s.io/client-go/util/retry"
 	"os"
+	"os/signal"
 	"path/filepath"
-	"time"
+	"strings"
+	"sync"
+	"syscall"
 
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
-
-	appsv1 "k8s.io/api/apps/v1"
-	apiv1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
 )
 
+// fileFlag collects every -f flag into a slice so manifests can be passed
+// more than once, e.g. "-f namespace.yaml -f deployment.yaml".
+type fileFlag []string
+
+func (f *fileFlag) String() string { return strings.Join(*f, ",") }
+
+func (f *fileFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
 func main() {
 	var kubeconfig *string
 	if home := homeDir(); home != "" {
@@ -26,8 +37,23 @@ func main() {
 	} else {
 		kubeconfig = flag.String("kubeconfig", "", "absolute path to the kubeconfig file")
 	}
+	var files fileFlag
+	flag.Var(&files, "f", "path to a manifest file or directory, YAML or JSON (may be repeated)")
+	prune := flag.Bool("prune", false, "delete previously-applied objects that are no longer present in the given manifests")
+	electionNamespace := flag.String("election-namespace", "default", "namespace the event controller's leader election Lease lives in")
+	metricsAddr := flag.String("metrics-addr", ":9100", "address to serve Prometheus metrics on; empty disables it")
+	eventWebhook := flag.String("event-webhook", "", "URL to POST each event to as JSON; empty disables it")
+	eventWorkers := flag.Int("event-workers", 1, "number of worker goroutines draining the event queue")
+	eventResyncPeriod := flag.Duration("event-resync-period", 0, "how often the event informer resyncs its store; 0 disables periodic resync")
+	eventLabelSelector := flag.String("event-label-selector", "", "restrict the event watch to events matching this label selector")
+	eventFieldSelector := flag.String("event-field-selector", "", "restrict the event watch to events matching this field selector")
 	flag.Parse()
 
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "at least one -f is required")
+		os.Exit(1)
+	}
+
 	// use the current context in kubeconfig
 	config, err := clientcmd.BuildConfigFromFlags("", *kubeconfig)
 	if err != nil {
@@ -40,147 +66,60 @@ func main() {
 		panic(err.Error())
 	}
 
-	// listen event
-	go utils.Watch(kubeconfig)
-
-	// 1 create namespace
-	name := "entry-task"
-	namespacesClient := clientset.CoreV1().Namespaces()
-	namespace := &apiv1.Namespace{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: name,
-		},
-		Status: apiv1.NamespaceStatus{
-			Phase: apiv1.NamespaceActive,
-		},
-	}
-	fmt.Println("Creating Namespaces...")
-	result, err := namespacesClient.Create(namespace)
-	if err != nil {
-		panic(err)
+	if *metricsAddr != "" {
+		go func() {
+			if err := metrics.Serve(*metricsAddr); err != nil {
+				klog.Errorf("metrics server stopped: %v", err)
+			}
+		}()
 	}
 
-	fmt.Printf("Created Namespaces %s on %s\n", result.ObjectMeta.Name, result.ObjectMeta.CreationTimestamp)
-
-	// 2 create deployment
-	deploymentName := "nginx-deployment"
-	deploymentsClient := clientset.AppsV1().Deployments(name)
-
-	deployment := &appsv1.Deployment{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: deploymentName,
-		},
-		Spec: appsv1.DeploymentSpec{
-			Replicas: int32Ptr(2),
-			Selector: &metav1.LabelSelector{
-				MatchLabels: map[string]string{
-					"app": "demo",
-				},
-			},
-			Template: apiv1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{
-						"app": "demo",
-					},
-				},
-				Spec: apiv1.PodSpec{
-					Containers: []apiv1.Container{
-						{
-							Name:  "web",
-							Image: "nginx:1.12",
-							Ports: []apiv1.ContainerPort{
-								{
-									Name:          "http",
-									Protocol:      apiv1.ProtocolTCP,
-									ContainerPort: 80,
-								},
-							},
-						},
-					},
-				},
-			},
-		},
-	}
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
 
-	fmt.Println("Creating deployment...")
-	deploymentResult, err := deploymentsClient.Create(deployment)
+	var watchers sync.WaitGroup
+	watchers.Add(2)
+	// listen event
+	go func() {
+		defer watchers.Done()
+		utils.Watch(ctx, kubeconfig, *electionNamespace, *eventWebhook, utils.EventWatchOptions{
+			Workers:       *eventWorkers,
+			ResyncPeriod:  *eventResyncPeriod,
+			LabelSelector: *eventLabelSelector,
+			FieldSelector: *eventFieldSelector,
+		})
+	}()
+	// reconcile WebApp custom resources into namespaces/deployments
+	go func() {
+		defer watchers.Done()
+		utils.WatchWebApps(ctx, kubeconfig)
+	}()
+
+	objs, err := apply.Load(files)
 	if err != nil {
 		panic(err)
 	}
-	fmt.Printf("Created deployment %q.\n", deploymentResult.GetObjectMeta().GetName())
-
-	// 3 update deployment
-	prompt()
-	fmt.Println("Updating deployment...")
-
-	cpuLimit := "700m"
-	memLimit := "200Mi"
-	retryErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		r, getErr := deploymentsClient.Get(deploymentName, metav1.GetOptions{})
-		if getErr != nil {
-			panic(fmt.Errorf("Failed to get latest version of Deployment: %v ", getErr))
-		}
 
-		r.Spec.Template.Spec.Containers[0].Image = "nginx:latest" // change nginx version
-		r.Spec.Template.Spec.Containers[0].Resources = apiv1.ResourceRequirements {
-			Limits: apiv1.ResourceList{
-				apiv1.ResourceCPU: resource.MustParse(cpuLimit),
-				apiv1.ResourceMemory: resource.MustParse(memLimit),
-			},
-			Requests: apiv1.ResourceList{
-				apiv1.ResourceCPU: resource.MustParse(cpuLimit),
-				apiv1.ResourceMemory: resource.MustParse(memLimit),
-			},
+	applier := apply.NewApplier(clientset)
+	fmt.Printf("Applying %d object(s)...\n", len(objs))
+	for _, obj := range objs {
+		if err := applier.Apply(obj); err != nil {
+			panic(err)
 		}
-		_, updateErr := deploymentsClient.Update(r)
-		return updateErr
-	})
-	if retryErr != nil {
-		panic(fmt.Errorf("Update failed: %v ", retryErr))
-	}
-	fmt.Println("Updated deployment...")
-
-	// List Deployments
-	prompt()
-	fmt.Printf("Listing deployments in namespace %q:\n", name)
-	list, err := deploymentsClient.List(metav1.ListOptions{})
-	if err != nil {
-		panic(err)
-	}
-	for _, d := range list.Items {
-		fmt.Printf(" * %s (%d replicas)\n", d.Name, *d.Spec.Replicas)
-	}
-
-	// Delete Deployment
-	prompt()
-	fmt.Println("Deleting deployment...")
-	deletePolicy := metav1.DeletePropagationForeground
-	if err := deploymentsClient.Delete(deploymentName, &metav1.DeleteOptions{
-		PropagationPolicy: &deletePolicy,
-	}); err != nil {
-		panic(err)
-	}
-	fmt.Println("Deleted deployment.")
-
-	// Delete Namespace
-	fmt.Println("Deleting Namespaces...")
-	deletePolicy = metav1.DeletePropagationForeground
-	if err := namespacesClient.Delete(name, &metav1.DeleteOptions{
-		PropagationPolicy: &deletePolicy,
-	}); err != nil {
-		panic(err)
 	}
-	fmt.Printf("Deleted Namespaces %s\n", name)
+	fmt.Println("Applied.")
 
-	for {
-		pods, err := clientset.CoreV1().Pods("").List(metav1.ListOptions{})
-		if err != nil {
-			panic(err.Error())
+	if *prune {
+		fmt.Println("Pruning objects no longer present in the given manifests...")
+		if err := applier.Prune(objs); err != nil {
+			panic(err)
 		}
-		fmt.Printf("There are %d pods in the cluster\n", len(pods.Items))
-
-		time.Sleep(10 * time.Second)
+		fmt.Println("Pruned.")
 	}
+
+	<-ctx.Done()
+	fmt.Println("Shutting down...")
+	watchers.Wait()
 }
 
 func homeDir() string {
@@ -189,17 +128,3 @@ func homeDir() string {
 	}
 	return os.Getenv("USERPROFILE") // windows
 }
-
-func prompt() {
-	fmt.Printf("-> Press Return key to continue.\n")
-	scanner := bufio.NewScanner(os.Stdin)
-	for scanner.Scan() {
-		break
-	}
-	if err := scanner.Err(); err != nil {
-		panic(err)
-	}
-	fmt.Println()
-}
-
-func int32Ptr(i int32) *int32 { return &i }
\ No newline at end of file
@@ -0,0 +1,126 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+// Clientset talks to the entrytask.io/v1 API group the way a client-gen
+// clientset would. It is hand-written rather than generated because the
+// group has a single resource, but it follows the same shape: a REST client
+// configured for the group version, and a per-resource getter.
+type Clientset struct {
+	restClient rest.Interface
+}
+
+// NewForConfig builds a Clientset from c, registering entrytask.io/v1 with
+// client-go's default scheme first so the REST client can (de)serialize
+// WebApp objects.
+func NewForConfig(c *rest.Config) (*Clientset, error) {
+	if err := AddToScheme(scheme.Scheme); err != nil {
+		return nil, err
+	}
+
+	config := *c
+	config.GroupVersion = &SchemeGroupVersion
+	config.APIPath = "/apis"
+	config.NegotiatedSerializer = serializer.NewCodecFactory(scheme.Scheme).WithoutConversion()
+	if config.UserAgent == "" {
+		config.UserAgent = rest.DefaultKubernetesUserAgent()
+	}
+
+	restClient, err := rest.RESTClientFor(&config)
+	if err != nil {
+		return nil, err
+	}
+	return &Clientset{restClient: restClient}, nil
+}
+
+// WebApps returns a client scoped to namespace. Pass metav1.NamespaceAll to
+// List or Watch across every namespace.
+func (c *Clientset) WebApps(namespace string) WebAppInterface {
+	return &webApps{client: c.restClient, ns: namespace}
+}
+
+// WebAppInterface has methods to work with WebApp resources in a namespace.
+type WebAppInterface interface {
+	Get(name string, options metav1.GetOptions) (*WebApp, error)
+	List(opts metav1.ListOptions) (*WebAppList, error)
+	Watch(opts metav1.ListOptions) (watch.Interface, error)
+	Create(webApp *WebApp) (*WebApp, error)
+	Update(webApp *WebApp) (*WebApp, error)
+	Delete(name string, options *metav1.DeleteOptions) error
+}
+
+type webApps struct {
+	client rest.Interface
+	ns     string
+}
+
+func (c *webApps) Get(name string, options metav1.GetOptions) (*WebApp, error) {
+	result := &WebApp{}
+	err := c.client.Get().
+		Namespace(c.ns).
+		Resource("webapps").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return result, err
+}
+
+func (c *webApps) List(opts metav1.ListOptions) (*WebAppList, error) {
+	result := &WebAppList{}
+	err := c.client.Get().
+		Namespace(c.ns).
+		Resource("webapps").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return result, err
+}
+
+func (c *webApps) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("webapps").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Watch()
+}
+
+func (c *webApps) Create(webApp *WebApp) (*WebApp, error) {
+	result := &WebApp{}
+	err := c.client.Post().
+		Namespace(c.ns).
+		Resource("webapps").
+		Body(webApp).
+		Do().
+		Into(result)
+	return result, err
+}
+
+func (c *webApps) Update(webApp *WebApp) (*WebApp, error) {
+	result := &WebApp{}
+	err := c.client.Put().
+		Namespace(c.ns).
+		Resource("webapps").
+		Name(webApp.Name).
+		Body(webApp).
+		Do().
+		Into(result)
+	return result, err
+}
+
+func (c *webApps) Delete(name string, options *metav1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("webapps").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
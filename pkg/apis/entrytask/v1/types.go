@@ -0,0 +1,51 @@
+// Package v1 contains the entrytask.io/v1 API: a WebApp custom resource
+// that lets users express "namespace + deployment + resource limits +
+// image" as a single declarative object instead of the typed Namespace and
+// Deployment manifests pkg/apply consumes directly.
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WebAppSpec is the desired state of a WebApp.
+type WebAppSpec struct {
+	// Namespace is the namespace the Deployment is materialized into. It is
+	// created if it does not already exist.
+	Namespace string `json:"namespace"`
+	// Image is the container image run by the generated Deployment.
+	Image string `json:"image"`
+	// Replicas is the desired replica count of the generated Deployment.
+	Replicas int32 `json:"replicas"`
+	// CPULimit is the CPU limit (and request) applied to the app container, e.g. "700m".
+	CPULimit string `json:"cpuLimit,omitempty"`
+	// MemoryLimit is the memory limit (and request) applied to the app container, e.g. "200Mi".
+	MemoryLimit string `json:"memoryLimit,omitempty"`
+}
+
+// WebAppStatus is the observed state of a WebApp. The WebApp controller in
+// utils does not write it: it reconciles the Namespace and Deployment only.
+type WebAppStatus struct {
+	// AvailableReplicas is the generated Deployment's available replica count.
+	// Nothing in this module sets it.
+	AvailableReplicas int32 `json:"availableReplicas"`
+}
+
+// WebApp is a namespace-scoped custom resource describing a single
+// application: the namespace, deployment image, replica count, and resource
+// limits it should run with.
+type WebApp struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WebAppSpec   `json:"spec,omitempty"`
+	Status WebAppStatus `json:"status,omitempty"`
+}
+
+// WebAppList is a list of WebApp objects.
+type WebAppList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []WebApp `json:"items"`
+}
@@ -0,0 +1,262 @@
+// Package apply implements a small "kubectl apply"-style reconciler: it
+// decodes one or more Kubernetes manifests and creates or updates the
+// matching objects in the cluster, tracking what it created so a later
+// prune pass can clean up objects that are no longer part of the input.
+package apply
+
+import (
+	"fmt"
+	"time"
+
+	"entryTask/pkg/rollout"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	typedappsv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+// rolloutTimeout bounds how long applyDeployment waits for a Deployment it
+// created or updated to become available. Only an updated Deployment is
+// rolled back when the wait fails; a newly created one is left as it is.
+const rolloutTimeout = 2 * time.Minute
+
+const (
+	// ManagedByLabel marks every object Apply creates so a later Prune can
+	// find objects that belonged to a previous apply but are no longer
+	// present in the current manifest set.
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	// ManagedByValue is the value ManagedByLabel is set to by this package.
+	ManagedByValue = "entryTask"
+)
+
+// Applier reconciles decoded manifest objects against a cluster using
+// create-or-update semantics.
+type Applier struct {
+	client kubernetes.Interface
+}
+
+// NewApplier returns an Applier that reconciles objects through client.
+func NewApplier(client kubernetes.Interface) *Applier {
+	return &Applier{client: client}
+}
+
+// Apply creates obj if it does not exist yet, or updates it in place
+// otherwise, retrying on update conflicts. It tags obj with ManagedByLabel
+// so Prune can later recognize it.
+func (a *Applier) Apply(obj runtime.Object) error {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return fmt.Errorf("apply: %v", err)
+	}
+	labels := accessor.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[ManagedByLabel] = ManagedByValue
+	accessor.SetLabels(labels)
+
+	switch o := obj.(type) {
+	case *apiv1.Namespace:
+		return a.applyNamespace(o)
+	case *apiv1.Service:
+		return a.applyService(o)
+	case *apiv1.ConfigMap:
+		return a.applyConfigMap(o)
+	case *apiv1.Secret:
+		return a.applySecret(o)
+	case *appsv1.Deployment:
+		return a.applyDeployment(o)
+	default:
+		return fmt.Errorf("apply: unsupported kind %T", obj)
+	}
+}
+
+func (a *Applier) applyNamespace(ns *apiv1.Namespace) error {
+	client := a.client.CoreV1().Namespaces()
+	_, err := client.Get(ns.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = client.Create(ns)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := client.Get(ns.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		existing.Labels = ns.Labels
+		existing.Annotations = ns.Annotations
+		_, err = client.Update(existing)
+		return err
+	})
+}
+
+// deploymentSnapshot is enough of a Deployment's previous state to fully
+// restore it: the whole Spec plus the Labels/Annotations applyDeployment
+// overwrites before updating.
+type deploymentSnapshot struct {
+	spec        appsv1.DeploymentSpec
+	labels      map[string]string
+	annotations map[string]string
+}
+
+// applyDeployment creates dep, or updates it in place if it already exists.
+// Either way, it then waits for the rollout to become available and, if an
+// update's rollout instead fails or times out, rolls the whole Deployment
+// back to what it was before the update.
+func (a *Applier) applyDeployment(dep *appsv1.Deployment) error {
+	if dep.Namespace == "" {
+		dep.Namespace = apiv1.NamespaceDefault
+	}
+	namespace := dep.Namespace
+	client := a.client.AppsV1().Deployments(namespace)
+
+	_, err := client.Get(dep.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		if _, err := client.Create(dep); err != nil {
+			return err
+		}
+		return rollout.WaitForDeployment(a.client, namespace, dep.Name, rolloutTimeout)
+	}
+	if err != nil {
+		return err
+	}
+
+	var previous deploymentSnapshot
+	retryErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := client.Get(dep.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		previous = deploymentSnapshot{
+			spec:        *existing.Spec.DeepCopy(),
+			labels:      existing.Labels,
+			annotations: existing.Annotations,
+		}
+		existing.Labels = dep.Labels
+		existing.Annotations = dep.Annotations
+		existing.Spec = dep.Spec
+		_, err = client.Update(existing)
+		return err
+	})
+	if retryErr != nil {
+		return retryErr
+	}
+
+	if err := rollout.WaitForDeployment(a.client, namespace, dep.Name, rolloutTimeout); err != nil {
+		if rbErr := rollback(client, dep.Name, previous); rbErr != nil {
+			return fmt.Errorf("rollout failed (%v) and rollback also failed: %v", err, rbErr)
+		}
+		return fmt.Errorf("rollout failed, rolled back %s: %v", dep.Name, err)
+	}
+	return nil
+}
+
+// rollback restores name's Spec, Labels, and Annotations to previous.
+func rollback(client typedappsv1.DeploymentInterface, name string, previous deploymentSnapshot) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := client.Get(name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		existing.Spec = previous.spec
+		existing.Labels = previous.labels
+		existing.Annotations = previous.annotations
+		_, err = client.Update(existing)
+		return err
+	})
+}
+
+func (a *Applier) applyService(svc *apiv1.Service) error {
+	if svc.Namespace == "" {
+		svc.Namespace = apiv1.NamespaceDefault
+	}
+	namespace := svc.Namespace
+	client := a.client.CoreV1().Services(namespace)
+	_, err := client.Get(svc.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = client.Create(svc)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := client.Get(svc.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		existing.Labels = svc.Labels
+		existing.Annotations = svc.Annotations
+		// ClusterIP is immutable once assigned; keep the live value.
+		svc.Spec.ClusterIP = existing.Spec.ClusterIP
+		existing.Spec = svc.Spec
+		_, err = client.Update(existing)
+		return err
+	})
+}
+
+func (a *Applier) applyConfigMap(cm *apiv1.ConfigMap) error {
+	if cm.Namespace == "" {
+		cm.Namespace = apiv1.NamespaceDefault
+	}
+	namespace := cm.Namespace
+	client := a.client.CoreV1().ConfigMaps(namespace)
+	_, err := client.Get(cm.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = client.Create(cm)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := client.Get(cm.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		existing.Labels = cm.Labels
+		existing.Annotations = cm.Annotations
+		existing.Data = cm.Data
+		existing.BinaryData = cm.BinaryData
+		_, err = client.Update(existing)
+		return err
+	})
+}
+
+func (a *Applier) applySecret(secret *apiv1.Secret) error {
+	if secret.Namespace == "" {
+		secret.Namespace = apiv1.NamespaceDefault
+	}
+	namespace := secret.Namespace
+	client := a.client.CoreV1().Secrets(namespace)
+	_, err := client.Get(secret.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = client.Create(secret)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := client.Get(secret.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		existing.Labels = secret.Labels
+		existing.Annotations = secret.Annotations
+		existing.Data = secret.Data
+		existing.StringData = secret.StringData
+		existing.Type = secret.Type
+		_, err = client.Update(existing)
+		return err
+	})
+}
@@ -0,0 +1,68 @@
+package apply
+
+import (
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestApplyDefaultsNamespaceOnObject is a regression test: Apply used to
+// default an empty namespace only for the client it built to Get/Create/
+// Update with, leaving the caller's object itself with Namespace == "" even
+// after the object was created in "default" — which broke Prune's
+// namespace-aware matching for it.
+func TestApplyDefaultsNamespaceOnObject(t *testing.T) {
+	applier := NewApplier(fake.NewSimpleClientset())
+
+	svc := &apiv1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web"}}
+	if err := applier.Apply(svc); err != nil {
+		t.Fatalf("Apply(svc): %v", err)
+	}
+	if svc.Namespace != apiv1.NamespaceDefault {
+		t.Fatalf("svc.Namespace = %q, want %q", svc.Namespace, apiv1.NamespaceDefault)
+	}
+
+	cm := &apiv1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "config"}}
+	if err := applier.Apply(cm); err != nil {
+		t.Fatalf("Apply(cm): %v", err)
+	}
+	if cm.Namespace != apiv1.NamespaceDefault {
+		t.Fatalf("cm.Namespace = %q, want %q", cm.Namespace, apiv1.NamespaceDefault)
+	}
+}
+
+func TestApplySetsManagedByLabel(t *testing.T) {
+	applier := NewApplier(fake.NewSimpleClientset())
+
+	svc := &apiv1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web"}}
+	if err := applier.Apply(svc); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if got := svc.Labels[ManagedByLabel]; got != ManagedByValue {
+		t.Fatalf("svc.Labels[%s] = %q, want %q", ManagedByLabel, got, ManagedByValue)
+	}
+}
+
+// TestPruneKeepsAppliedObjectInDefaultNamespace is a regression test for the
+// same bug as TestApplyDefaultsNamespaceOnObject, exercised end to end:
+// applying a manifest with no metadata.namespace and then pruning against
+// that same object must not delete what was just applied.
+func TestPruneKeepsAppliedObjectInDefaultNamespace(t *testing.T) {
+	applier := NewApplier(fake.NewSimpleClientset())
+
+	svc := &apiv1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web"}}
+	if err := applier.Apply(svc); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	if err := applier.Prune([]runtime.Object{svc}); err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+
+	if _, err := applier.client.CoreV1().Services(apiv1.NamespaceDefault).Get("web", metav1.GetOptions{}); err != nil {
+		t.Fatalf("Service was pruned even though it's still in desired: %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package apply
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// Load decodes the Kubernetes manifests found at paths into typed objects.
+// Each entry in paths may be a single YAML or JSON file, or a directory, in
+// which case every .yaml/.yml/.json file directly inside it is read. A
+// single file may contain multiple "---"-separated documents.
+func Load(paths []string) ([]runtime.Object, error) {
+	var files []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, fmt.Errorf("stat %s: %v", p, err)
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		entries, err := ioutil.ReadDir(p)
+		if err != nil {
+			return nil, fmt.Errorf("read dir %s: %v", p, err)
+		}
+		for _, e := range entries {
+			if e.IsDir() {
+				continue
+			}
+			switch strings.ToLower(filepath.Ext(e.Name())) {
+			case ".yaml", ".yml", ".json":
+				files = append(files, filepath.Join(p, e.Name()))
+			}
+		}
+	}
+
+	decoder := scheme.Codecs.UniversalDeserializer()
+	var objs []runtime.Object
+	for _, f := range files {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %v", f, err)
+		}
+		reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+		for {
+			doc, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("split documents in %s: %v", f, err)
+			}
+			if len(bytes.TrimSpace(doc)) == 0 {
+				continue
+			}
+			obj, _, err := decoder.Decode(doc, nil, nil)
+			if err != nil {
+				return nil, fmt.Errorf("decode document in %s: %v", f, err)
+			}
+			objs = append(objs, obj)
+		}
+	}
+	return objs, nil
+}
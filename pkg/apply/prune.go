@@ -0,0 +1,119 @@
+package apply
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// key identifies a namespaced (or cluster-scoped) object by kind and name.
+type key struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+// Prune deletes objects previously created by Apply that are no longer part
+// of desired. Only the kinds Apply knows how to create (Namespace,
+// Deployment, Service, ConfigMap, Secret) are considered.
+func (a *Applier) Prune(desired []runtime.Object) error {
+	want := map[key]bool{}
+	for _, obj := range desired {
+		k, err := keyOf(obj)
+		if err != nil {
+			return err
+		}
+		want[k] = true
+	}
+
+	selector := metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", ManagedByLabel, ManagedByValue)}
+
+	namespaces, err := a.client.CoreV1().Namespaces().List(selector)
+	if err != nil {
+		return err
+	}
+	for _, ns := range namespaces.Items {
+		if !want[key{kind: "Namespace", name: ns.Name}] {
+			if err := a.client.CoreV1().Namespaces().Delete(ns.Name, &metav1.DeleteOptions{}); err != nil {
+				return err
+			}
+		}
+	}
+
+	deployments, err := a.client.AppsV1().Deployments(apiv1.NamespaceAll).List(selector)
+	if err != nil {
+		return err
+	}
+	for _, dep := range deployments.Items {
+		if !want[key{kind: "Deployment", namespace: dep.Namespace, name: dep.Name}] {
+			if err := a.client.AppsV1().Deployments(dep.Namespace).Delete(dep.Name, &metav1.DeleteOptions{}); err != nil {
+				return err
+			}
+		}
+	}
+
+	services, err := a.client.CoreV1().Services(apiv1.NamespaceAll).List(selector)
+	if err != nil {
+		return err
+	}
+	for _, svc := range services.Items {
+		if !want[key{kind: "Service", namespace: svc.Namespace, name: svc.Name}] {
+			if err := a.client.CoreV1().Services(svc.Namespace).Delete(svc.Name, &metav1.DeleteOptions{}); err != nil {
+				return err
+			}
+		}
+	}
+
+	configMaps, err := a.client.CoreV1().ConfigMaps(apiv1.NamespaceAll).List(selector)
+	if err != nil {
+		return err
+	}
+	for _, cm := range configMaps.Items {
+		if !want[key{kind: "ConfigMap", namespace: cm.Namespace, name: cm.Name}] {
+			if err := a.client.CoreV1().ConfigMaps(cm.Namespace).Delete(cm.Name, &metav1.DeleteOptions{}); err != nil {
+				return err
+			}
+		}
+	}
+
+	secrets, err := a.client.CoreV1().Secrets(apiv1.NamespaceAll).List(selector)
+	if err != nil {
+		return err
+	}
+	for _, secret := range secrets.Items {
+		if !want[key{kind: "Secret", namespace: secret.Namespace, name: secret.Name}] {
+			if err := a.client.CoreV1().Secrets(secret.Namespace).Delete(secret.Name, &metav1.DeleteOptions{}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func keyOf(obj runtime.Object) (key, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return key{}, fmt.Errorf("prune: %v", err)
+	}
+	var kind string
+	switch obj.(type) {
+	case *apiv1.Namespace:
+		kind = "Namespace"
+	case *apiv1.Service:
+		kind = "Service"
+	case *apiv1.ConfigMap:
+		kind = "ConfigMap"
+	case *apiv1.Secret:
+		kind = "Secret"
+	case *appsv1.Deployment:
+		kind = "Deployment"
+	default:
+		return key{}, fmt.Errorf("prune: unsupported kind %T", obj)
+	}
+	return key{kind: kind, namespace: accessor.GetNamespace(), name: accessor.GetName()}, nil
+}
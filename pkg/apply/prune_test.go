@@ -0,0 +1,26 @@
+package apply
+
+import (
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestKeyOfUsesObjectNamespace(t *testing.T) {
+	svc := &apiv1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+	got, err := keyOf(svc)
+	if err != nil {
+		t.Fatalf("keyOf: %v", err)
+	}
+	want := key{kind: "Service", namespace: "default", name: "web"}
+	if got != want {
+		t.Fatalf("keyOf(svc) = %+v, want %+v", got, want)
+	}
+}
+
+func TestKeyOfRejectsUnsupportedKind(t *testing.T) {
+	if _, err := keyOf(&apiv1.Pod{}); err == nil {
+		t.Fatal("keyOf(Pod): expected an error for an unsupported kind")
+	}
+}
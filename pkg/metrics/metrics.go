@@ -0,0 +1,51 @@
+// Package metrics exposes a Prometheus /metrics endpoint and the counters,
+// histograms, and workqueue.MetricsProvider the controllers in utils
+// instrument themselves with.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	// EventsProcessed counts events a controller has handled, by resource and event type (CREATE/UPDATE/DELETE).
+	EventsProcessed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "entrytask_events_processed_total",
+		Help: "Number of informer events processed, by resource and event type.",
+	}, []string{"resource", "type"})
+
+	// RetriesTotal counts how many times a sync was requeued after failing, by resource.
+	RetriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "entrytask_sync_retries_total",
+		Help: "Number of times a sync was retried after failing, by resource.",
+	}, []string{"resource"})
+
+	// ReconcileErrors counts syncs that were dropped after exhausting retries, by resource.
+	ReconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "entrytask_reconcile_errors_total",
+		Help: "Number of syncs dropped after exhausting retries, by resource.",
+	}, []string{"resource"})
+
+	// SyncLatency observes how long a single sync call took, by resource.
+	SyncLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "entrytask_sync_latency_seconds",
+		Help:    "Time spent in a single sync call, by resource.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"resource"})
+)
+
+func init() {
+	prometheus.MustRegister(EventsProcessed, RetriesTotal, ReconcileErrors, SyncLatency)
+}
+
+// Serve starts an HTTP server exposing the registered metrics at /metrics on
+// addr. It blocks, so callers run it in its own goroutine, e.g.
+// "go metrics.Serve(*metricsAddr)".
+func Serve(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	return http.ListenAndServe(addr, mux)
+}
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// These back a workqueue.MetricsProvider so every named workqueue created
+// after this package is imported (see init below) reports its depth,
+// latency, and retries the same way ResourceController in utils reports its
+// own sync latency and error counts. Unnamed workqueues report nothing.
+var (
+	queueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "entrytask_workqueue_depth",
+		Help: "Current depth of a workqueue, by queue name.",
+	}, []string{"name"})
+
+	queueAdds = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "entrytask_workqueue_adds_total",
+		Help: "Total items added to a workqueue, by queue name.",
+	}, []string{"name"})
+
+	queueLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "entrytask_workqueue_queue_latency_seconds",
+		Help:    "How long an item sat in a workqueue before being processed, by queue name.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"name"})
+
+	queueWorkDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "entrytask_workqueue_work_duration_seconds",
+		Help:    "How long it took to process an item popped from a workqueue, by queue name.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"name"})
+
+	queueUnfinishedWork = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "entrytask_workqueue_unfinished_work_seconds",
+		Help: "Seconds of work that is in progress but not finished, by queue name.",
+	}, []string{"name"})
+
+	queueLongestRunningProcessor = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "entrytask_workqueue_longest_running_processor_seconds",
+		Help: "How long the longest-running processor has been running, by queue name.",
+	}, []string{"name"})
+
+	queueRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "entrytask_workqueue_retries_total",
+		Help: "Total retries handled by a workqueue, by queue name.",
+	}, []string{"name"})
+)
+
+func init() {
+	prometheus.MustRegister(queueDepth, queueAdds, queueLatency, queueWorkDuration, queueUnfinishedWork, queueLongestRunningProcessor, queueRetries)
+	workqueue.SetProvider(queueMetricsProvider{})
+}
+
+// queueMetricsProvider implements workqueue.MetricsProvider on top of the
+// Prometheus vectors above.
+type queueMetricsProvider struct{}
+
+func (queueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return queueDepth.WithLabelValues(name)
+}
+
+func (queueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return queueAdds.WithLabelValues(name)
+}
+
+func (queueMetricsProvider) NewLatencyMetric(name string) workqueue.HistogramMetric {
+	return queueLatency.WithLabelValues(name)
+}
+
+func (queueMetricsProvider) NewWorkDurationMetric(name string) workqueue.HistogramMetric {
+	return queueWorkDuration.WithLabelValues(name)
+}
+
+func (queueMetricsProvider) NewUnfinishedWorkSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return queueUnfinishedWork.WithLabelValues(name)
+}
+
+func (queueMetricsProvider) NewLongestRunningProcessorSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return queueLongestRunningProcessor.WithLabelValues(name)
+}
+
+func (queueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return queueRetries.WithLabelValues(name)
+}
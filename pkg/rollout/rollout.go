@@ -0,0 +1,198 @@
+// Package rollout waits for a Deployment update to roll out successfully,
+// analogous to "kubectl rollout status", and reports which pods are failing
+// if it does not.
+package rollout
+
+import (
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// FailedPod describes a single pod observed in a failing state during a rollout.
+type FailedPod struct {
+	Name   string
+	Reason string
+}
+
+// Error is returned by WaitForDeployment when a rollout times out or a pod
+// fails to start; it lists every pod observed in a failing state.
+type Error struct {
+	Namespace  string
+	Deployment string
+	Failed     []FailedPod
+}
+
+func (e *Error) Error() string {
+	if len(e.Failed) == 0 {
+		return fmt.Sprintf("rollout of %s/%s did not become available in time", e.Namespace, e.Deployment)
+	}
+	return fmt.Sprintf("rollout of %s/%s failed: %d pod(s) unhealthy: %v", e.Namespace, e.Deployment, len(e.Failed), e.Failed)
+}
+
+// WaitForDeployment blocks until the Deployment ns/name has rolled out
+// successfully (observedGeneration caught up, updatedReplicas and
+// availableReplicas match spec.replicas, and neither the Progressing nor the
+// Available condition is present with a status other than True; a condition
+// that is absent does not hold the rollout back), or returns a *Error once timeout elapses or a
+// pod is seen failing to start (ImagePullBackOff/ErrImagePull/CrashLoopBackOff),
+// whichever happens first.
+func WaitForDeployment(client kubernetes.Interface, ns, name string, timeout time.Duration) error {
+	deployment, err := client.AppsV1().Deployments(ns).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("rollout: get %s/%s: %v", ns, name, err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return fmt.Errorf("rollout: selector for %s/%s: %v", ns, name, err)
+	}
+
+	failures := make(chan FailedPod, failureBufferSize(deployment))
+	stop := make(chan struct{})
+	defer close(stop)
+	watchFailingPods(client, ns, selector.String(), failures, stop)
+
+	deadline := time.After(timeout)
+	poll := time.NewTicker(2 * time.Second)
+	defer poll.Stop()
+
+	var failed []FailedPod
+	for {
+		select {
+		case f := <-failures:
+			failed = append(failed, f)
+			failed = append(failed, drainFailures(failures)...)
+			return &Error{Namespace: ns, Deployment: name, Failed: failed}
+
+		case <-poll.C:
+			dep, err := client.AppsV1().Deployments(ns).Get(name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("rollout: get %s/%s: %v", ns, name, err)
+			}
+			if rolloutComplete(dep) {
+				return nil
+			}
+
+		case <-deadline:
+			failed = append(failed, drainFailures(failures)...)
+			return &Error{Namespace: ns, Deployment: name, Failed: failed}
+		}
+	}
+}
+
+// drainFailures collects every FailedPod already buffered on failures
+// without blocking, so a caller about to return an *Error picks up pods that
+// failed around the same time as the one that triggered the return instead
+// of dropping them unread.
+func drainFailures(failures <-chan FailedPod) []FailedPod {
+	var drained []FailedPod
+	for {
+		select {
+		case f := <-failures:
+			drained = append(drained, f)
+		default:
+			return drained
+		}
+	}
+}
+
+// minFailureBuffer is the floor failureBufferSize returns, so a Deployment
+// with few or no replicas still has room to buffer a handful of failures.
+const minFailureBuffer = 16
+
+// failureBufferSize sizes the failures channel off how many pods a rollout
+// of dep could have failing at once: up to Spec.Replicas pods, plus as many
+// again for the surge pods a RollingUpdate can run alongside them, so
+// watchFailingPods' non-blocking send never silently drops a failure just
+// because more than minFailureBuffer replicas rolled out badly.
+func failureBufferSize(dep *appsv1.Deployment) int {
+	replicas := int32(1)
+	if dep.Spec.Replicas != nil {
+		replicas = *dep.Spec.Replicas
+	}
+	size := int(replicas) * 2
+	if size < minFailureBuffer {
+		size = minFailureBuffer
+	}
+	return size
+}
+
+func rolloutComplete(dep *appsv1.Deployment) bool {
+	wanted := int32(1)
+	if dep.Spec.Replicas != nil {
+		wanted = *dep.Spec.Replicas
+	}
+	if dep.Status.ObservedGeneration < dep.Generation {
+		return false
+	}
+	if dep.Status.UpdatedReplicas < wanted || dep.Status.AvailableReplicas < wanted {
+		return false
+	}
+	for _, c := range dep.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing && c.Status != apiv1.ConditionTrue {
+			return false
+		}
+		if c.Type == appsv1.DeploymentAvailable && c.Status != apiv1.ConditionTrue {
+			return false
+		}
+	}
+	return true
+}
+
+// watchFailingPods runs a Pod informer scoped to labelSelector in ns until
+// stop is closed, sending every pod it sees in a failing state on failures.
+func watchFailingPods(client kubernetes.Interface, ns, labelSelector string, failures chan<- FailedPod, stop <-chan struct{}) {
+	listWatch := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.LabelSelector = labelSelector
+			return client.CoreV1().Pods(ns).List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.LabelSelector = labelSelector
+			return client.CoreV1().Pods(ns).Watch(options)
+		},
+	}
+
+	check := func(obj interface{}) {
+		pod, ok := obj.(*apiv1.Pod)
+		if !ok {
+			return
+		}
+		if reason, failing := failingReason(pod); failing {
+			select {
+			case failures <- FailedPod{Name: pod.Name, Reason: reason}:
+			default:
+			}
+		}
+	}
+
+	_, informer := cache.NewInformer(listWatch, &apiv1.Pod{}, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc:    check,
+		UpdateFunc: func(old, new interface{}) { check(new) },
+	})
+	go informer.Run(stop)
+}
+
+// failingReason reports a pod as failing once a container is stuck waiting
+// on ImagePullBackOff/ErrImagePull/CrashLoopBackOff. A single terminated
+// container with RestartCount > 0 is not by itself treated as a failure:
+// kubelet already retries transient crashes, and CrashLoopBackOff is what
+// catches a container that keeps failing.
+func failingReason(pod *apiv1.Pod) (string, bool) {
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Waiting != nil {
+			switch cs.State.Waiting.Reason {
+			case "ImagePullBackOff", "ErrImagePull", "CrashLoopBackOff":
+				return cs.State.Waiting.Reason, true
+			}
+		}
+	}
+	return "", false
+}
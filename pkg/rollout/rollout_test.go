@@ -0,0 +1,74 @@
+package rollout
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRolloutComplete(t *testing.T) {
+	replicas := int32(3)
+	complete := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Generation: 2},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 2,
+			UpdatedReplicas:    3,
+			AvailableReplicas:  3,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentProgressing, Status: apiv1.ConditionTrue},
+				{Type: appsv1.DeploymentAvailable, Status: apiv1.ConditionTrue},
+			},
+		},
+	}
+	if !rolloutComplete(complete) {
+		t.Fatal("rolloutComplete(complete) = false, want true")
+	}
+
+	stale := complete.DeepCopy()
+	stale.Status.ObservedGeneration = 1
+	if rolloutComplete(stale) {
+		t.Fatal("rolloutComplete(stale ObservedGeneration) = true, want false")
+	}
+
+	short := complete.DeepCopy()
+	short.Status.AvailableReplicas = 2
+	if rolloutComplete(short) {
+		t.Fatal("rolloutComplete(AvailableReplicas < replicas) = true, want false")
+	}
+
+	notAvailable := complete.DeepCopy()
+	notAvailable.Status.Conditions[1].Status = apiv1.ConditionFalse
+	if rolloutComplete(notAvailable) {
+		t.Fatal("rolloutComplete(Available condition False) = true, want false")
+	}
+}
+
+func TestFailureBufferSize(t *testing.T) {
+	small := int32(2)
+	if got := failureBufferSize(&appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: &small}}); got != minFailureBuffer {
+		t.Fatalf("failureBufferSize(2 replicas) = %d, want floor of %d", got, minFailureBuffer)
+	}
+
+	large := int32(50)
+	if got, want := failureBufferSize(&appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: &large}}), 100; got != want {
+		t.Fatalf("failureBufferSize(50 replicas) = %d, want %d", got, want)
+	}
+}
+
+func TestDrainFailures(t *testing.T) {
+	ch := make(chan FailedPod, 2)
+	ch <- FailedPod{Name: "a"}
+	ch <- FailedPod{Name: "b"}
+
+	got := drainFailures(ch)
+	if len(got) != 2 {
+		t.Fatalf("drainFailures returned %d pod(s), want 2", len(got))
+	}
+
+	if more := drainFailures(ch); more != nil {
+		t.Fatalf("drainFailures on an empty channel = %v, want nil", more)
+	}
+}
@@ -0,0 +1,251 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"entryTask/pkg/metrics"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// CreateEvent event associated with new objects in an informer
+	CreateEvent = "CREATE"
+	// UpdateEvent event associated with an object update in an informer
+	UpdateEvent = "UPDATE"
+	// DeleteEvent event associated when an object is removed from an informer
+	DeleteEvent = "DELETE"
+)
+
+// Controller runs a single resource watch loop until stopCh is closed.
+type Controller interface {
+	Run(stopCh <-chan struct{})
+}
+
+// Handler reacts to the Add/Update/Delete events a Controller observes for
+// its watched resource. obj is the current object for Add/Update, and the
+// last known object (possibly nil, if it could not be recovered) for Delete.
+type Handler interface {
+	OnAdd(key string, obj k8sruntime.Object) error
+	OnUpdate(key string, obj k8sruntime.Object) error
+	OnDelete(key string, obj k8sruntime.Object) error
+}
+
+// Options configures a ResourceController.
+type Options struct {
+	// Resource is the GVR to watch, e.g. EventsResource or PodsResource.
+	Resource schema.GroupVersionResource
+	// Namespace restricts the watch to a single namespace; "" watches all namespaces.
+	Namespace string
+	// Workers is the number of worker goroutines draining the queue. Defaults to 1.
+	Workers int
+	// ResyncPeriod is how often the informer resyncs its store. 0 disables periodic resync.
+	ResyncPeriod time.Duration
+	// LabelSelector restricts the watch to objects matching it, e.g. "app=demo".
+	LabelSelector string
+	// FieldSelector restricts the watch to objects matching it, e.g. "involvedObject.kind=Pod".
+	FieldSelector string
+	// Handler receives the events the controller observes.
+	Handler Handler
+}
+
+// queuedEvent is what ResourceController puts on its workqueue: a key to
+// look up, the kind of event that triggered it, and, for deletes, the last
+// known object (the indexer no longer has it to look up by key).
+type queuedEvent struct {
+	key       string
+	kind      string
+	tombstone k8sruntime.Object
+}
+
+// ResourceController watches a single resource type through a
+// SharedInformerFactory and dispatches events to a Handler, retrying failed
+// handler calls with a rate-limited workqueue.
+type ResourceController struct {
+	opts     Options
+	queue    workqueue.RateLimitingInterface
+	indexer  cache.Indexer
+	informer cache.SharedIndexInformer
+}
+
+// NewResourceController builds a ResourceController for opts.Resource.
+func NewResourceController(clientset kubernetes.Interface, opts Options) (*ResourceController, error) {
+	if opts.Workers == 0 {
+		opts.Workers = 1
+	}
+	if opts.Handler == nil {
+		return nil, fmt.Errorf("watch %s: a Handler is required", opts.Resource)
+	}
+
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, opts.ResyncPeriod,
+		informers.WithNamespace(opts.Namespace),
+		informers.WithTweakListOptions(func(listOpts *metav1.ListOptions) {
+			listOpts.LabelSelector = opts.LabelSelector
+			listOpts.FieldSelector = opts.FieldSelector
+		}),
+	)
+	generic, err := factory.ForResource(opts.Resource)
+	if err != nil {
+		return nil, fmt.Errorf("watch %s: %v", opts.Resource, err)
+	}
+
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), opts.Resource.Resource)
+	informer := generic.Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if key, err := cache.MetaNamespaceKeyFunc(obj); err == nil {
+				queue.Add(queuedEvent{key: key, kind: CreateEvent})
+			}
+		},
+		UpdateFunc: func(old, new interface{}) {
+			if key, err := cache.MetaNamespaceKeyFunc(new); err == nil {
+				queue.Add(queuedEvent{key: key, kind: UpdateEvent})
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+			if err != nil {
+				return
+			}
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			runtimeObj, _ := obj.(k8sruntime.Object)
+			queue.Add(queuedEvent{key: key, kind: DeleteEvent, tombstone: runtimeObj})
+		},
+	})
+
+	return &ResourceController{
+		opts:     opts,
+		queue:    queue,
+		indexer:  informer.GetIndexer(),
+		informer: informer,
+	}, nil
+}
+
+// Run starts the informer and opts.Workers worker goroutines, and blocks
+// until stopCh is closed. On shutdown it drains the workqueue (lets workers
+// finish the items already in flight) before returning, so closing stopCh
+// because of a lost leader election or a signal never drops in-progress work.
+func (c *ResourceController) Run(stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+
+	klog.Infof("Starting %s controller", c.opts.Resource.Resource)
+	go c.informer.Run(stopCh)
+
+	// Wait for all involved caches to be synced, before processing items from the queue is started
+	if !cache.WaitForCacheSync(stopCh, c.informer.HasSynced) {
+		utilruntime.HandleError(fmt.Errorf("timed out waiting for %s cache to sync", c.opts.Resource.Resource))
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < c.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wait.Until(c.runWorker, time.Second, stopCh)
+		}()
+	}
+
+	<-stopCh
+	klog.Infof("Stopping %s controller, draining workqueue", c.opts.Resource.Resource)
+	c.queue.ShutDownWithDrain()
+	wg.Wait()
+}
+
+func (c *ResourceController) runWorker() {
+	for c.processNextItem() {
+	}
+}
+
+func (c *ResourceController) processNextItem() bool {
+	item, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(item)
+
+	err := c.sync(item.(queuedEvent))
+	c.handleErr(err, item)
+	return true
+}
+
+// sync dispatches evt to opts.Handler. For Add/Update it re-fetches the
+// object from the indexer so a worker always sees the freshest version,
+// rather than whatever version triggered the original enqueue.
+func (c *ResourceController) sync(evt queuedEvent) error {
+	start := time.Now()
+	defer func() {
+		metrics.SyncLatency.WithLabelValues(c.opts.Resource.Resource).Observe(time.Since(start).Seconds())
+	}()
+
+	err := c.dispatch(evt)
+	if err == nil {
+		metrics.EventsProcessed.WithLabelValues(c.opts.Resource.Resource, evt.kind).Inc()
+	}
+	return err
+}
+
+func (c *ResourceController) dispatch(evt queuedEvent) error {
+	if evt.kind == DeleteEvent {
+		return c.opts.Handler.OnDelete(evt.key, evt.tombstone)
+	}
+
+	obj, exists, err := c.indexer.GetByKey(evt.key)
+	if err != nil {
+		klog.Errorf("Fetching object with key %s from store failed with %v", evt.key, err)
+		return err
+	}
+	if !exists {
+		return c.opts.Handler.OnDelete(evt.key, nil)
+	}
+
+	runtimeObj, ok := obj.(k8sruntime.Object)
+	if !ok {
+		return fmt.Errorf("object %s is not a runtime.Object", evt.key)
+	}
+	if evt.kind == CreateEvent {
+		return c.opts.Handler.OnAdd(evt.key, runtimeObj)
+	}
+	return c.opts.Handler.OnUpdate(evt.key, runtimeObj)
+}
+
+// handleErr retries a failed sync up to 5 times before giving up on it.
+func (c *ResourceController) handleErr(err error, item interface{}) {
+	if err == nil {
+		// Forget about the #AddRateLimited history of the key on every successful synchronization.
+		// This ensures that future processing of updates for this key is not delayed because of
+		// an outdated error history.
+		c.queue.Forget(item)
+		return
+	}
+
+	// This controller retries 5 times if something goes wrong. After that, it stops trying.
+	if c.queue.NumRequeues(item) < 5 {
+		klog.Infof("Error syncing %s %v: %v", c.opts.Resource.Resource, item, err)
+		metrics.RetriesTotal.WithLabelValues(c.opts.Resource.Resource).Inc()
+
+		// Re-enqueue the key rate limited. Based on the rate limiter on the
+		// queue and the re-enqueue history, the key will be processed later again.
+		c.queue.AddRateLimited(item)
+		return
+	}
+
+	c.queue.Forget(item)
+	metrics.ReconcileErrors.WithLabelValues(c.opts.Resource.Resource).Inc()
+	// Report to an external entity that, even after several retries, we could not successfully process this key
+	utilruntime.HandleError(err)
+	klog.Infof("Dropping %s %v out of the queue: %v", c.opts.Resource.Resource, item, err)
+}
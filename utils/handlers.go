@@ -0,0 +1,192 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// StdoutHandler prints one line per event to stdout.
+type StdoutHandler struct{}
+
+// OnAdd implements Handler.
+func (StdoutHandler) OnAdd(key string, obj k8sruntime.Object) error {
+	fmt.Printf("%s %s: %+v\n", CreateEvent, key, obj)
+	return nil
+}
+
+// OnUpdate implements Handler.
+func (StdoutHandler) OnUpdate(key string, obj k8sruntime.Object) error {
+	fmt.Printf("%s %s: %+v\n", UpdateEvent, key, obj)
+	return nil
+}
+
+// OnDelete implements Handler.
+func (StdoutHandler) OnDelete(key string, obj k8sruntime.Object) error {
+	fmt.Printf("%s %s\n", DeleteEvent, key)
+	return nil
+}
+
+// FileHandler appends one text line per event to w. It is safe for
+// concurrent use by multiple controller workers.
+type FileHandler struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewFileHandler returns a FileHandler that writes through w.
+func NewFileHandler(w io.Writer) *FileHandler {
+	return &FileHandler{w: w}
+}
+
+func (h *FileHandler) writeLine(kind, key string, obj k8sruntime.Object) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, fmt.Sprintf("%s %s: %+v\n", kind, key, obj))
+	return err
+}
+
+// OnAdd implements Handler.
+func (h *FileHandler) OnAdd(key string, obj k8sruntime.Object) error { return h.writeLine(CreateEvent, key, obj) }
+
+// OnUpdate implements Handler.
+func (h *FileHandler) OnUpdate(key string, obj k8sruntime.Object) error {
+	return h.writeLine(UpdateEvent, key, obj)
+}
+
+// OnDelete implements Handler.
+func (h *FileHandler) OnDelete(key string, obj k8sruntime.Object) error {
+	return h.writeLine(DeleteEvent, key, obj)
+}
+
+// jsonLineEvent is the wire format JSONLinesHandler writes, one per line.
+type jsonLineEvent struct {
+	Type   string            `json:"type"`
+	Key    string            `json:"key"`
+	Object k8sruntime.Object `json:"object,omitempty"`
+}
+
+// JSONLinesHandler writes one JSON object per line, for forwarding events to
+// log-shipping pipelines that expect JSON lines rather than free text.
+type JSONLinesHandler struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONLinesHandler returns a JSONLinesHandler that writes through w.
+func NewJSONLinesHandler(w io.Writer) *JSONLinesHandler {
+	return &JSONLinesHandler{w: w}
+}
+
+func (h *JSONLinesHandler) write(kind, key string, obj k8sruntime.Object) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return json.NewEncoder(h.w).Encode(jsonLineEvent{Type: kind, Key: key, Object: obj})
+}
+
+// OnAdd implements Handler.
+func (h *JSONLinesHandler) OnAdd(key string, obj k8sruntime.Object) error {
+	return h.write(CreateEvent, key, obj)
+}
+
+// OnUpdate implements Handler.
+func (h *JSONLinesHandler) OnUpdate(key string, obj k8sruntime.Object) error {
+	return h.write(UpdateEvent, key, obj)
+}
+
+// OnDelete implements Handler.
+func (h *JSONLinesHandler) OnDelete(key string, obj k8sruntime.Object) error {
+	return h.write(DeleteEvent, key, obj)
+}
+
+// WebhookHandler POSTs each event as a JSON body to url, so events can be
+// forwarded to an external system (a chat webhook, an ingest endpoint, ...).
+type WebhookHandler struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookHandler returns a WebhookHandler that POSTs to url using client.
+// If client is nil, a client with a 10s timeout is used.
+func NewWebhookHandler(url string, client *http.Client) *WebhookHandler {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &WebhookHandler{url: url, client: client}
+}
+
+func (h *WebhookHandler) post(kind, key string, obj k8sruntime.Object) error {
+	body, err := json.Marshal(jsonLineEvent{Type: kind, Key: key, Object: obj})
+	if err != nil {
+		return fmt.Errorf("webhook: marshal event for %s: %v", key, err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook: build request for %s: %v", key, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: POST %s for %s: %v", h.url, key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook: POST %s for %s returned %s", h.url, key, resp.Status)
+	}
+	return nil
+}
+
+// OnAdd implements Handler.
+func (h *WebhookHandler) OnAdd(key string, obj k8sruntime.Object) error { return h.post(CreateEvent, key, obj) }
+
+// OnUpdate implements Handler.
+func (h *WebhookHandler) OnUpdate(key string, obj k8sruntime.Object) error {
+	return h.post(UpdateEvent, key, obj)
+}
+
+// OnDelete implements Handler.
+func (h *WebhookHandler) OnDelete(key string, obj k8sruntime.Object) error {
+	return h.post(DeleteEvent, key, obj)
+}
+
+// MultiHandler fans out each event to every Handler in order, stopping and
+// returning the first error encountered.
+type MultiHandler []Handler
+
+// OnAdd implements Handler.
+func (m MultiHandler) OnAdd(key string, obj k8sruntime.Object) error {
+	for _, h := range m {
+		if err := h.OnAdd(key, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OnUpdate implements Handler.
+func (m MultiHandler) OnUpdate(key string, obj k8sruntime.Object) error {
+	for _, h := range m {
+		if err := h.OnUpdate(key, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OnDelete implements Handler.
+func (m MultiHandler) OnDelete(key string, obj k8sruntime.Object) error {
+	for _, h := range m {
+		if err := h.OnDelete(key, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
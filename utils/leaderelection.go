@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog/v2"
+)
+
+// RunWithLeaderElection runs controller only while this process holds the
+// Lease named leaseName in namespace, so two replicas of the same controller
+// never run at once and double-write their sink. Canceling ctx (e.g. from a
+// signal) releases the lease, closes the controller's stopCh, and
+// RunWithLeaderElection returns once it has stopped.
+func RunWithLeaderElection(ctx context.Context, clientset kubernetes.Interface, namespace, leaseName string, controller Controller) error {
+	identity, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("leader election: %v", err)
+	}
+	identity = fmt.Sprintf("%s_%d", identity, os.Getpid())
+
+	lock, err := resourcelock.New(resourcelock.LeasesResourceLock, namespace, leaseName,
+		clientset.CoreV1(), clientset.CoordinationV1(),
+		resourcelock.ResourceLockConfig{Identity: identity},
+	)
+	if err != nil {
+		return fmt.Errorf("leader election: %v", err)
+	}
+
+	started := make(chan struct{})
+	stopped := make(chan struct{})
+	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		ReleaseOnCancel: true,
+		LeaseDuration:   15 * time.Second,
+		RenewDeadline:   10 * time.Second,
+		RetryPeriod:     2 * time.Second,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(leaderCtx context.Context) {
+				close(started)
+				defer close(stopped)
+
+				klog.Infof("%s: started leading %s/%s", identity, namespace, leaseName)
+
+				stopCh := make(chan struct{})
+				go func() {
+					<-leaderCtx.Done()
+					close(stopCh)
+				}()
+				controller.Run(stopCh)
+			},
+			OnStoppedLeading: func() {
+				klog.Infof("%s: stopped leading %s/%s", identity, namespace, leaseName)
+			},
+		},
+	})
+
+	select {
+	case <-started:
+		// RunOrDie returns as soon as renewal stops, without waiting for the
+		// OnStartedLeading goroutine it spawned to finish; block here so the
+		// controller's workqueue has actually drained before we return.
+		<-stopped
+	default:
+		// OnStartedLeading never ran, e.g. ctx was canceled before acquiring the lease.
+	}
+	return nil
+}
@@ -1,234 +1,96 @@
 package utils
 
 import (
-	"fmt"
-	"io"
+	"context"
 	"os"
-	"reflect"
 	"time"
 
-	"k8s.io/klog/v2"
-
-	v1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/fields"
-	"k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
-	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
 )
 
-const (
-	// CreateEvent event associated with new objects in an informer
-	CreateEvent = "CREATE"
-	// UpdateEvent event associated with an object update in an informer
-	UpdateEvent = "UPDATE"
-	// DeleteEvent event associated when an object is removed from an informer
-	DeleteEvent = "DELETE"
+// GVRs for the built-in resources ResourceController knows how to watch.
+// Pass one of these (or any other built-in GVR the installed client-go
+// version supports) as Options.Resource.
+var (
+	PodsResource        = apiv1.SchemeGroupVersion.WithResource("pods")
+	EventsResource      = apiv1.SchemeGroupVersion.WithResource("events")
+	DeploymentsResource = appsv1.SchemeGroupVersion.WithResource("deployments")
+	ServicesResource    = apiv1.SchemeGroupVersion.WithResource("services")
 )
 
-type Controller struct {
-	indexer  cache.Indexer
-	queue    workqueue.RateLimitingInterface
-	informer cache.Controller
-	f        *os.File
-}
-
-type Event struct {
-	Type string
-	Obj  *v1.Event
-	Key  string
-}
-
-func NewController(queue workqueue.RateLimitingInterface, indexer cache.Indexer, informer cache.Controller, f *os.File) *Controller {
-	return &Controller{
-		informer: informer,
-		indexer:  indexer,
-		queue:    queue,
-		f:		  f,
-	}
-}
-
-func (c *Controller) processNextItem() bool {
-	// Wait until there is a new item in the working queue
-	newEvent, quit := c.queue.Get()
-	if quit {
-		return false
-	}
-	// Tell the queue that we are done with processing this key. This unblocks the key for other workers
-	// This allows safe parallel processing because two pods with the same key are never processed in
-	// parallel.
-	defer c.queue.Done(newEvent)
-
-	// Invoke the method containing the business logic
-	err := c.syncToStdout(newEvent.(string))
-	// Handle the error if something went wrong during the execution of the business logic
-	c.handleErr(err, newEvent)
-	return true
+// eventControllerLease is the Lease name the event controller elects a
+// leader under, so two replicas never both append to watch.txt.
+const eventControllerLease = "entrytask-event-controller"
+
+// EventWatchOptions configures the parts of the Events ResourceController
+// that Watch builds that a caller might reasonably want to change: how many
+// workers drain the queue, how often the informer resyncs its store, and
+// which events it watches in the first place.
+type EventWatchOptions struct {
+	// Workers is the number of worker goroutines draining the queue. Defaults to 1.
+	Workers int
+	// ResyncPeriod is how often the informer resyncs its store. 0 disables periodic resync.
+	ResyncPeriod time.Duration
+	// LabelSelector restricts the watch to events matching it, e.g. "app=demo".
+	LabelSelector string
+	// FieldSelector restricts the watch to events matching it, e.g. "involvedObject.kind=Pod".
+	FieldSelector string
 }
 
-// syncToStdout is the business logic of the controller. In this controller it simply prints
-// information about the pod to stdout. In case an error happened, it has to simply return the error.
-// The retry logic should not be part of the business logic.
-func (c *Controller) syncToStdout(key string) error {
-	obj, exists, err := c.indexer.GetByKey(key)
-	if err != nil {
-		klog.Errorf("Fetching object with key %s from store failed with %v", key, err)
-		return err
-	}
-
-	if !exists {
-		// Below we will warm up our cache with a Pod, so that we will see a delete for one pod
-		fmt.Printf("Pod %s does not exist anymore\n", key)
-		//io.WriteString(c.f, fmt.Sprintf("Delete %s \n", key))
-	} else {
-		// Note that you also have to check the uid if you have a local controlled resource, which
-		// is dependent on the actual instance, to detect that a Pod was recreated with the same name
-		evt := obj.(*v1.Event)
-		msg := fmt.Sprintf("%s: %s/%s/%s/%s \n", evt.Name, evt.Namespace, evt.Kind, evt.Name, evt.Message)
-		fmt.Printf(msg)
-		_, err := io.WriteString(c.f, msg)
-		if err != nil {
-			fmt.Printf("write file error %s", err.Error())
-		}
-	}
-	return nil
-}
-
-// handleErr checks if an error happened and makes sure we will retry later.
-func (c *Controller) handleErr(err error, key interface{}) {
-	if err == nil {
-		// Forget about the #AddRateLimited history of the key on every successful synchronization.
-		// This ensures that future processing of updates for this key is not delayed because of
-		// an outdated error history.
-		c.queue.Forget(key)
-		return
-	}
-
-	// This controller retries 5 times if something goes wrong. After that, it stops trying.
-	if c.queue.NumRequeues(key) < 5 {
-		klog.Infof("Error syncing pod %v: %v", key, err)
-
-		// Re-enqueue the key rate limited. Based on the rate limiter on the
-		// queue and the re-enqueue history, the key will be processed later again.
-		c.queue.AddRateLimited(key)
-		return
-	}
-
-	c.queue.Forget(key)
-	// Report to an external entity that, even after several retries, we could not successfully process this key
-	runtime.HandleError(err)
-	klog.Infof("Dropping pod %q out of the queue: %v", key, err)
-}
-
-func (c *Controller) Run(threadiness int, stopCh chan struct{}) {
-	defer runtime.HandleCrash()
-
-	// Let the workers stop when we are done
-	defer c.queue.ShutDown()
-	klog.Info("Starting Pod controller")
-
-	go c.informer.Run(stopCh)
-
-	// Wait for all involved caches to be synced, before processing items from the queue is started
-	//if !cache.WaitForCacheSync(stopCh, c.informer.HasSynced) {
-	//	runtime.HandleError(fmt.Errorf("Timed out waiting for caches to sync "))
-	//	return
-	//}
-
-	for i := 0; i < threadiness; i++ {
-		go wait.Until(c.runWorker, time.Second, stopCh)
-	}
-
-	<-stopCh
-	klog.Info("Stopping Pod controller")
-}
-
-func (c *Controller) runWorker() {
-	for c.processNextItem() {
-	}
-}
-
-func Watch(kubeconfig *string) {
-	// use the current context in kubeconfig
+// Watch watches cluster Events in every namespace and appends one JSON line
+// per event to ./watch.txt, optionally also POSTing each event to webhookURL
+// (ignored if empty). It only runs while holding the eventControllerLease
+// Lease in electionNamespace, so it is safe to run as multiple replicas. Once
+// ctx is canceled (e.g. by the caller on SIGINT/SIGTERM) it releases the
+// lease, drains the workqueue, and closes watch.txt before returning.
+func Watch(ctx context.Context, kubeconfig *string, electionNamespace, webhookURL string, opts EventWatchOptions) {
 	config, err := clientcmd.BuildConfigFromFlags("", *kubeconfig)
 	if err != nil {
 		panic(err.Error())
 	}
 
-	// creates the clientset
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		klog.Fatal(err)
+		panic(err.Error())
 	}
 
-	// create the pod watcher
-	podListWatcher := cache.NewListWatchFromClient(clientset.CoreV1().RESTClient(), "events", v1.NamespaceAll, fields.Everything())
-
-	// create the workqueue
-	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
-	var newEvent Event
-
-	// Bind the workqueue to a cache with the help of an informer. This way we make sure that
-	// whenever the cache is updated, the pod key is added to the workqueue.
-	// Note that when we finally process the item from the workqueue, we might see a newer version
-	// of the Pod than the version which was responsible for triggering the update.
-	indexer, informer := cache.NewIndexerInformer(podListWatcher, &v1.Event{}, 0, cache.ResourceEventHandlerFuncs{
-		AddFunc: func(obj interface{}) {
-			evt := obj.(*v1.Event)
-			newEvent.Obj = evt
-			newEvent.Type = CreateEvent
-			newEvent.Key, err = cache.MetaNamespaceKeyFunc(obj)
-			if err == nil {
-				queue.Add(newEvent.Key)
-			}
-		},
-		UpdateFunc: func(old, new interface{}) {
-			oldEvt := old.(*v1.Event)
-			newEvt := new.(*v1.Event)
-			if !reflect.DeepEqual(newEvt.Source, oldEvt.Source) && oldEvt.Reason != newEvt.Reason {
-				newEvent.Obj = newEvt
-				newEvent.Type = UpdateEvent
-				newEvent.Key, err = cache.MetaNamespaceKeyFunc(old)
-				if err == nil {
-					queue.Add(newEvent.Key)
-				}
-			}
-		},
-		DeleteFunc: func(obj interface{}) {
-			evt := obj.(*v1.Event)
-			newEvent.Obj = evt
-			newEvent.Type = DeleteEvent
-			newEvent.Key, err = cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
-			if err == nil {
-				queue.Add(newEvent.Key)
-			}
-		},
-	}, cache.Indexers{})
-
-	// file
-	var filename = "./watch.txt"
+	filename := "./watch.txt"
 	var f *os.File
-	var err1 error
 	if _, err := os.Stat(filename); os.IsNotExist(err) {
-		f, err1 = os.Create(filename)
+		f, err = os.Create(filename)
 	} else {
-		f, err1 = os.OpenFile(filename, os.O_APPEND | os.O_RDWR, 0666)
+		f, err = os.OpenFile(filename, os.O_APPEND|os.O_RDWR, 0666)
 	}
-	if err1 != nil {
-		panic(err1)
+	if err != nil {
+		panic(err)
 	}
 
-	defer f.Close()
+	handlers := MultiHandler{StdoutHandler{}, NewJSONLinesHandler(f)}
+	if webhookURL != "" {
+		handlers = append(handlers, NewWebhookHandler(webhookURL, nil))
+	}
 
-	controller := NewController(queue, indexer, informer, f)
+	controller, err := NewResourceController(clientset, Options{
+		Resource:      EventsResource,
+		Workers:       opts.Workers,
+		ResyncPeriod:  opts.ResyncPeriod,
+		LabelSelector: opts.LabelSelector,
+		FieldSelector: opts.FieldSelector,
+		Handler:       handlers,
+	})
+	if err != nil {
+		panic(err)
+	}
 
-	// Now let's start the controller
-	stop := make(chan struct{})
-	defer close(stop)
-	go controller.Run(1, stop)
+	if err := RunWithLeaderElection(ctx, clientset, electionNamespace, eventControllerLease, controller); err != nil {
+		panic(err)
+	}
 
-	// Wait forever
-	select {}
+	if err := f.Close(); err != nil {
+		klog.Errorf("closing %s: %v", filename, err)
+	}
 }
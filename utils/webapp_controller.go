@@ -0,0 +1,257 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"entryTask/pkg/apply"
+	entrytaskv1 "entryTask/pkg/apis/entrytask/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+)
+
+// WebAppController reconciles WebApp custom resources into the Namespace and
+// Deployment they describe, the same way main.go used to hard-code a single
+// namespace/deployment pair by hand.
+type WebAppController struct {
+	applier  *apply.Applier
+	queue    workqueue.RateLimitingInterface
+	indexer  cache.Indexer
+	informer cache.Controller
+}
+
+// NewWebAppController returns a WebAppController that reconciles through applier.
+func NewWebAppController(applier *apply.Applier, queue workqueue.RateLimitingInterface, indexer cache.Indexer, informer cache.Controller) *WebAppController {
+	return &WebAppController{applier: applier, queue: queue, indexer: indexer, informer: informer}
+}
+
+func (c *WebAppController) Run(threadiness int, stopCh chan struct{}) {
+	defer utilruntime.HandleCrash()
+	var wg sync.WaitGroup
+	// Deferred before queue.ShutDown so it runs after it: workers only exit
+	// once the queue is shut down.
+	defer wg.Wait()
+	defer c.queue.ShutDown()
+	klog.Info("Starting WebApp controller")
+
+	go c.informer.Run(stopCh)
+
+	if !cache.WaitForCacheSync(stopCh, c.informer.HasSynced) {
+		utilruntime.HandleError(fmt.Errorf("timed out waiting for WebApp cache to sync"))
+		return
+	}
+
+	for i := 0; i < threadiness; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wait.Until(c.runWorker, time.Second, stopCh)
+		}()
+	}
+
+	<-stopCh
+	klog.Info("Stopping WebApp controller")
+}
+
+func (c *WebAppController) runWorker() {
+	for c.processNextItem() {
+	}
+}
+
+func (c *WebAppController) processNextItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	err := c.reconcile(key.(string))
+	c.handleErr(err, key)
+	return true
+}
+
+// reconcile materializes the Namespace and Deployment described by the
+// WebApp named by key.
+func (c *WebAppController) reconcile(key string) error {
+	obj, exists, err := c.indexer.GetByKey(key)
+	if err != nil {
+		klog.Errorf("Fetching WebApp %s from store failed with %v", key, err)
+		return err
+	}
+	if !exists {
+		klog.Infof("WebApp %s has been deleted", key)
+		return nil
+	}
+
+	app, ok := obj.(*entrytaskv1.WebApp)
+	if !ok {
+		return fmt.Errorf("unexpected object type %T for WebApp %s", obj, key)
+	}
+
+	namespace := &apiv1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: app.Spec.Namespace},
+	}
+	if err := c.applier.Apply(namespace); err != nil {
+		return fmt.Errorf("apply namespace for WebApp %s: %v", key, err)
+	}
+
+	deployment, err := deploymentForWebApp(app)
+	if err != nil {
+		return fmt.Errorf("build deployment for WebApp %s: %v", key, err)
+	}
+	if err := c.applier.Apply(deployment); err != nil {
+		return fmt.Errorf("apply deployment for WebApp %s: %v", key, err)
+	}
+
+	return nil
+}
+
+// deploymentForWebApp builds the Deployment a WebApp describes. It returns
+// an error instead of panicking if CPULimit or MemoryLimit is not a valid
+// resource.Quantity, since both come from user-supplied, format-unvalidated
+// CRD fields.
+func deploymentForWebApp(app *entrytaskv1.WebApp) (*appsv1.Deployment, error) {
+	replicas := app.Spec.Replicas
+	if replicas == 0 {
+		replicas = 1
+	}
+	labels := map[string]string{"app": app.Name}
+
+	container := apiv1.Container{
+		Name:  "app",
+		Image: app.Spec.Image,
+		Ports: []apiv1.ContainerPort{
+			{Name: "http", Protocol: apiv1.ProtocolTCP, ContainerPort: 80},
+		},
+	}
+	if app.Spec.CPULimit != "" || app.Spec.MemoryLimit != "" {
+		limits := apiv1.ResourceList{}
+		if app.Spec.CPULimit != "" {
+			cpu, err := resource.ParseQuantity(app.Spec.CPULimit)
+			if err != nil {
+				return nil, fmt.Errorf("parse cpuLimit %q: %v", app.Spec.CPULimit, err)
+			}
+			limits[apiv1.ResourceCPU] = cpu
+		}
+		if app.Spec.MemoryLimit != "" {
+			mem, err := resource.ParseQuantity(app.Spec.MemoryLimit)
+			if err != nil {
+				return nil, fmt.Errorf("parse memoryLimit %q: %v", app.Spec.MemoryLimit, err)
+			}
+			limits[apiv1.ResourceMemory] = mem
+		}
+		container.Resources = apiv1.ResourceRequirements{Limits: limits, Requests: limits}
+	}
+
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      app.Name,
+			Namespace: app.Spec.Namespace,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: apiv1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       apiv1.PodSpec{Containers: []apiv1.Container{container}},
+			},
+		},
+	}, nil
+}
+
+// handleErr retries key up to 5 times, mirroring ResourceController.handleErr.
+func (c *WebAppController) handleErr(err error, key interface{}) {
+	if err == nil {
+		c.queue.Forget(key)
+		return
+	}
+
+	if c.queue.NumRequeues(key) < 5 {
+		klog.Infof("Error syncing WebApp %v: %v", key, err)
+		c.queue.AddRateLimited(key)
+		return
+	}
+
+	c.queue.Forget(key)
+	utilruntime.HandleError(err)
+	klog.Infof("Dropping WebApp %q out of the queue: %v", key, err)
+}
+
+// WatchWebApps lists and watches WebApp custom resources and reconciles each
+// one into its Namespace and Deployment, parallel to Watch for Events. It
+// blocks until ctx is canceled (e.g. by the caller on SIGINT/SIGTERM), then
+// stops the informer and workers before returning.
+func WatchWebApps(ctx context.Context, kubeconfig *string) {
+	config, err := clientcmd.BuildConfigFromFlags("", *kubeconfig)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		klog.Fatal(err)
+	}
+
+	webAppClient, err := entrytaskv1.NewForConfig(config)
+	if err != nil {
+		klog.Fatal(err)
+	}
+
+	listWatch := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (k8sruntime.Object, error) {
+			return webAppClient.WebApps(metav1.NamespaceAll).List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return webAppClient.WebApps(metav1.NamespaceAll).Watch(options)
+		},
+	}
+
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	indexer, informer := cache.NewIndexerInformer(listWatch, &entrytaskv1.WebApp{}, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			key, err := cache.MetaNamespaceKeyFunc(obj)
+			if err == nil {
+				queue.Add(key)
+			}
+		},
+		UpdateFunc: func(old, new interface{}) {
+			key, err := cache.MetaNamespaceKeyFunc(new)
+			if err == nil {
+				queue.Add(key)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+			if err == nil {
+				queue.Add(key)
+			}
+		},
+	}, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+
+	controller := NewWebAppController(apply.NewApplier(clientset), queue, indexer, informer)
+
+	stop := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		close(stop)
+	}()
+	// Each reconcile can block for up to rolloutTimeout waiting on a
+	// Deployment rollout, so give WebApps several workers rather than
+	// serializing them behind a single one. Run blocks until stop is
+	// closed, which happens once ctx is canceled.
+	controller.Run(5, stop)
+}